@@ -0,0 +1,30 @@
+package httprouter
+
+import "fmt"
+
+// ErrorKind区分ErrorHandler接收到的*RouterError的来源
+type ErrorKind int
+
+const (
+	// ErrorKindPanic表示处理函数执行时发生了panic
+	ErrorKindPanic ErrorKind = iota
+	// ErrorKindNotFound表示没有匹配的路由
+	ErrorKindNotFound
+	// ErrorKindMethodNotAllowed表示路径匹配但是方法不匹配
+	ErrorKindMethodNotAllowed
+	// ErrorKindBadRequest表示请求路径本身不合法(例如既不以'/'开头也不是"*")，
+	// 无法进入前缀树查找
+	ErrorKindBadRequest
+)
+
+// RouterError是ErrorHandler统一接收的错误类型，Cause保存recover()得到的原始值，
+// Stack仅在Kind为ErrorKindPanic时携带debug.Stack()输出的堆栈信息
+type RouterError struct {
+	Kind  ErrorKind
+	Cause interface{}
+	Stack []byte
+}
+
+func (e *RouterError) Error() string {
+	return fmt.Sprintf("httprouter: %v", e.Cause)
+}
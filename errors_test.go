@@ -0,0 +1,57 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandlerReceivesEachKind(t *testing.T) {
+	r := New()
+	r.GET("/panics", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		panic("boom")
+	})
+	r.GET("/ok", noopHandle)
+
+	var got ErrorKind
+	var gotCause interface{}
+	r.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		rerr, ok := err.(*RouterError)
+		if !ok {
+			t.Fatalf("err = %T, want *RouterError", err)
+		}
+		got = rerr.Kind
+		gotCause = rerr.Cause
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   ErrorKind
+	}{
+		{"panic", "GET", "/panics", ErrorKindPanic},
+		{"not found", "GET", "/missing", ErrorKindNotFound},
+		{"method not allowed", "POST", "/ok", ErrorKindMethodNotAllowed},
+		{"bad request", "GET", "relative/path", ErrorKindBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotCause = -1, nil
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "http://example.com/placeholder", nil)
+			req.URL.Path = tc.path
+			r.ServeHTTP(w, req)
+
+			if got != tc.want {
+				t.Fatalf("Kind = %v, want %v", got, tc.want)
+			}
+			if tc.want == ErrorKindPanic && gotCause != "boom" {
+				t.Fatalf("Cause = %v, want %q", gotCause, "boom")
+			}
+		})
+	}
+}
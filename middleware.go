@@ -0,0 +1,170 @@
+package httprouter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Middleware包装Handle函数，用于在请求处理前后插入公共逻辑，例如日志、鉴权
+type Middleware func(Handle) Handle
+
+// wrapMiddleware按注册顺序(FIFO)包裹handle，mw[0]最先执行
+func wrapMiddleware(handle Handle, mw []Middleware) Handle {
+	for i := len(mw) - 1; i >= 0; i-- {
+		handle = mw[i](handle)
+	}
+	return handle
+}
+
+// Use注册全局中间件，按FIFO顺序包裹注册时间点之后新增的路由
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// UseStd将标准net/http中间件适配成Middleware，方便复用gorilla、alice等生态的组件
+// Params通过context传递给内层处理函数，适配方式与Router.Handler一致
+func (r *Router) UseStd(mw func(http.Handler) http.Handler) {
+	r.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			if len(ps) > 0 {
+				ps = append(Params(nil), ps...)
+			}
+			ctx := context.WithValue(req.Context(), ParamsKey, ps)
+			req = req.WithContext(ctx)
+			mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next(w, req, ParamsFromContext(req.Context()))
+			})).ServeHTTP(w, req)
+		}
+	})
+}
+
+// RouterGroup是共享前缀和中间件的子路由，复用Router的前缀树
+type RouterGroup struct {
+	router     *Router
+	prefix     string
+	middleware []Middleware
+
+	// 分组级别的NotFound/MethodNotAllowed覆盖，在注册时(NotFoundHandler/
+	// MethodNotAllowedHandler)确定，ServeHTTP按请求路径最长前缀匹配分组
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+// Group基于当前Router创建1个子路由，prefix会拼接到该分组下所有注册路径之前
+// mw会在Router已注册的全局中间件之后按FIFO顺序追加执行
+func (r *Router) Group(prefix string, mw ...Middleware) *RouterGroup {
+	combined := make([]Middleware, 0, len(r.middleware)+len(mw))
+	combined = append(combined, r.middleware...)
+	combined = append(combined, mw...)
+	g := &RouterGroup{
+		router:     r,
+		prefix:     prefix,
+		middleware: combined,
+	}
+	r.groups = append(r.groups, g)
+	return g
+}
+
+// NotFoundHandler为该分组前缀下的请求注册专属的404处理函数，
+// 优先于Router.ErrorHandler和Router.NotFound
+func (g *RouterGroup) NotFoundHandler(handler http.Handler) {
+	g.notFound = handler
+}
+
+// MethodNotAllowedHandler为该分组前缀下的请求注册专属的405处理函数，
+// 优先于Router.ErrorHandler和Router.MethodNotAllowed
+func (g *RouterGroup) MethodNotAllowedHandler(handler http.Handler) {
+	g.methodNotAllowed = handler
+}
+
+// groupFor按最长前缀匹配查找path所属的分组，没有匹配的分组返回nil
+// 前缀必须命中完整的路径分段，例如分组"/api"匹配"/api"和"/api/x"，
+// 但不能匹配"/apikeys"
+//
+// 每个分组的NotFound/MethodNotAllowed覆盖在注册时(NotFoundHandler/
+// MethodNotAllowedHandler)就已经确定，但"哪个分组管辖当前请求路径"
+// 这件事只能在请求到来、拿到具体path之后才能判断，因此groupFor仍然是
+// ServeHTTP每次404/405时的O(分组数)线性扫描，并不是O(1)；分组数量
+// 通常远小于路由数量，这里用线性扫描换取实现的简单性，没有为此引入
+// 额外的前缀树结构
+func (r *Router) groupFor(path string) *RouterGroup {
+	var best *RouterGroup
+	for _, g := range r.groups {
+		if (path == g.prefix || strings.HasPrefix(path, g.prefix+"/")) &&
+			(best == nil || len(g.prefix) > len(best.prefix)) {
+			best = g
+		}
+	}
+	return best
+}
+
+// Group基于当前分组创建子分组，前缀和中间件均累加
+func (g *RouterGroup) Group(prefix string, mw ...Middleware) *RouterGroup {
+	combined := make([]Middleware, 0, len(g.middleware)+len(mw))
+	combined = append(combined, g.middleware...)
+	combined = append(combined, mw...)
+	child := &RouterGroup{
+		router:     g.router,
+		prefix:     g.prefix + prefix,
+		middleware: combined,
+	}
+	g.router.groups = append(g.router.groups, child)
+	return child
+}
+
+// Use为分组追加中间件，仅作用于调用之后在该分组注册的路由
+func (g *RouterGroup) Use(mw ...Middleware) {
+	g.middleware = append(g.middleware, mw...)
+}
+
+// Handle在分组前缀下注册路由，按FIFO顺序包裹Router和分组累积的中间件
+func (g *RouterGroup) Handle(method, path string, handle Handle) {
+	g.router.handleWithMiddleware(method, g.prefix+path, handle, g.middleware)
+}
+
+func (g *RouterGroup) GET(path string, handle Handle) {
+	g.Handle("GET", path, handle)
+}
+
+func (g *RouterGroup) HEAD(path string, handle Handle) {
+	g.Handle("HEAD", path, handle)
+}
+
+func (g *RouterGroup) OPTIONS(path string, handle Handle) {
+	g.Handle("OPTIONS", path, handle)
+}
+
+func (g *RouterGroup) POST(path string, handle Handle) {
+	g.Handle("POST", path, handle)
+}
+
+func (g *RouterGroup) PUT(path string, handle Handle) {
+	g.Handle("PUT", path, handle)
+}
+
+func (g *RouterGroup) PATCH(path string, handle Handle) {
+	g.Handle("PATCH", path, handle)
+}
+
+func (g *RouterGroup) DELETE(path string, handle Handle) {
+	g.Handle("DELETE", path, handle)
+}
+
+// Handler将http.Handler适配成分组路由的处理函数
+func (g *RouterGroup) Handler(method, path string, handler http.Handler) {
+	g.Handle(method, path, func(w http.ResponseWriter, req *http.Request, ps Params) {
+		// ps可能来自Router的paramsPool，拷贝1份再放入context，避免handler返回之后
+		// 仍然持有context时读到被复用覆盖的数据
+		if len(ps) > 0 {
+			ps = append(Params(nil), ps...)
+		}
+		ctx := context.WithValue(req.Context(), ParamsKey, ps)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// HandlerFunc将http.HandlerFunc适配成分组路由的处理函数
+func (g *RouterGroup) HandlerFunc(method, path string, handler http.HandlerFunc) {
+	g.Handler(method, path, handler)
+}
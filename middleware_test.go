@@ -0,0 +1,68 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func orderMiddleware(name string, trace *[]string) Middleware {
+	return func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			*trace = append(*trace, name)
+			next(w, req, ps)
+		}
+	}
+}
+
+func TestGroupComposesGlobalAndGroupMiddleware(t *testing.T) {
+	var trace []string
+
+	r := New()
+	r.Use(orderMiddleware("global", &trace))
+
+	g := r.Group("/api", orderMiddleware("group", &trace))
+	g.GET("/ping", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		trace = append(trace, "handler")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/ping", nil)
+	r.ServeHTTP(w, req)
+
+	want := []string{"global", "group", "handler"}
+	if len(trace) != len(want) {
+		t.Fatalf("trace = %v, want %v", trace, want)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("trace = %v, want %v", trace, want)
+		}
+	}
+}
+
+func TestGroupForRequiresSegmentBoundary(t *testing.T) {
+	r := New()
+	g := r.Group("/api")
+
+	var notFoundHit bool
+	g.NotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		notFoundHit = true
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/apikeys/rotate", nil)
+	r.ServeHTTP(w, req)
+
+	if notFoundHit {
+		t.Fatalf("group registered at '/api' must not match '/apikeys/rotate'")
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/missing", nil)
+	r.ServeHTTP(w, req)
+
+	if !notFoundHit {
+		t.Fatalf("group registered at '/api' must match '/api/missing'")
+	}
+}
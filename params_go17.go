@@ -17,6 +17,12 @@ var ParamsKey = paramsKey{}
 func (r *Router) Handler(method, path string, handler http.Handler) {
 	r.Handle(method, path,
 		func(w http.ResponseWriter, req *http.Request, p Params) {
+			// p可能来自Router的paramsPool，ServeHTTP处理函数返回之后就会被复用，
+			// 这里拷贝1份放入context，避免底层http.Handler在handler返回之后
+			// 仍然持有context(例如另起goroutine)时读到被覆盖的数据
+			if len(p) > 0 {
+				p = append(Params(nil), p...)
+			}
 			ctx := req.Context()
 			ctx = context.WithValue(ctx, ParamsKey, p)
 			req = req.WithContext(ctx)
@@ -0,0 +1,53 @@
+package httprouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type pusherKey struct{}
+
+// PusherKey是http.Pusher存入请求上下文的键，配合Pusher函数使用
+var PusherKey = pusherKey{}
+
+// Push是http.ResponseWriter.Push的简单包装，当ResponseWriter不支持HTTP/2
+// Server Push(例如HTTP/1.1连接)的时候返回错误，方便处理函数统一处理
+func Push(w http.ResponseWriter, target string, opts *http.PushOptions) error {
+	pusher, ok := w.(http.Pusher)
+	if !ok {
+		return errors.New("httprouter: ResponseWriter does not support HTTP/2 server push")
+	}
+	return pusher.Push(target, opts)
+}
+
+// Pusher从请求上下文获取http.Pusher，需要Router.EnablePush为true才会被注入
+// 如果当前连接不支持HTTP/2 Server Push返回nil
+func Pusher(ctx context.Context) http.Pusher {
+	pusher, _ := ctx.Value(PusherKey).(http.Pusher)
+	return pusher
+}
+
+// PushMap为path注册1组需要随之推送的伴随资源(例如css、js)
+// path使用ServeFiles请求时实际命中的文件路径，而不是注册时的*filepath模式
+// 仅在Router.EnablePush为true且底层连接支持HTTP/2 Server Push时生效
+func (r *Router) PushMap(path string, assets []string) {
+	if r.pushAssets == nil {
+		r.pushAssets = make(map[string][]string)
+	}
+	r.pushAssets[path] = assets
+}
+
+// pushAssetsFor尝试推送path注册的伴随资源，静默忽略推送失败(例如客户端已经缓存)
+func (r *Router) pushAssetsFor(w http.ResponseWriter, path string) {
+	if !r.EnablePush {
+		return
+	}
+	pusher, ok := w.(http.Pusher)
+	if !ok {
+		return
+	}
+	for _, asset := range r.pushAssets[path] {
+		pusher.Push(asset, nil)
+	}
+}
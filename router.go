@@ -61,7 +61,10 @@
 package httprouter
 
 import (
+	"context"
 	"net/http"
+	"runtime/debug"
+	"sync"
 )
 
 // Handle函数用于处理请求，类似http.HandlerFunc
@@ -87,8 +90,13 @@ func (ps Params) ByName(name string) string {
 
 // Router是http.Handler的实现，通过配置分发请求到不同的处理函数
 type Router struct {
-	// 前缀树，用于存储路由配置信息
-	trees map[string]*node
+	// 前缀树，用于存储路由配置信息，默认为基数树，UseRegexTree开启之后
+	// 或者路径包含{name:pattern}约束参数时使用支持正则表达式的实现
+	trees map[string]routeTree
+
+	// 配置是否全局使用支持正则表达式约束参数的路由树，而不是仅在
+	// 路径包含{name:pattern}时才启用；默认false，静态路由仍然走基数树
+	UseRegexTree bool
 
 	// 配置是否自动重定向带斜杠的请求：
 	// 如果只存在/foo的路由信息，/foo/重定向到/foo
@@ -103,12 +111,59 @@ type Router struct {
 	// 配置是否自动回复OPTIONS请求
 	HandleOPTIONS bool
 
+	// 配置是否在ResponseWriter支持HTTP/2 Server Push时，将http.Pusher注入请求
+	// 上下文(通过Pusher函数获取)，并在ServeFiles命中PushMap注册的资源时主动推送
+	EnablePush bool
+	// path(ServeFiles实际命中的文件路径) -> 需要随之推送的伴随资源
+	pushAssets map[string][]string
+
 	// 没有匹配的路由的时候，可以注册1个处理函数
 	NotFound http.Handler
 	// 存在匹配的路由，但是方法不匹配的时候，可以注册1个处理函数
 	MethodNotAllowed http.Handler
 	// 请求处理出现异常，可以注册1个处理函数
+	// 已不推荐使用，优先配置ErrorHandler，2者同时配置时只有ErrorHandler生效
 	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
+
+	// ErrorHandler统一接收panic、NotFound、MethodNotAllowed、BadRequest这4类错误，
+	// 通过*RouterError.Kind区分来源；配置之后优先于PanicHandler、NotFound、
+	// MethodNotAllowed（分组级别的NotFound/MethodNotAllowed除外）
+	ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+	// 全局中间件，按注册顺序(FIFO)包裹之后通过Handle注册的处理函数
+	middleware []Middleware
+	// 已创建的分组，用于在NotFound/MethodNotAllowed时按最长前缀匹配分组级别的覆盖
+	groups []*RouterGroup
+
+	// 已注册路由的记录，供Routes()展示；name -> 原始路径模板，供URL()反向生成路径
+	routes         []RouteInfo
+	routeTemplates map[string]string
+
+	// MaxParams是当前已注册路由中单条路径最多的参数个数，addRoute时自动更新，
+	// 用于初始化paramsPool中Params切片的容量
+	MaxParams int
+	// paramsPool缓存ServeHTTP查找过程中使用的Params切片，命中之后归还，
+	// 避免每次请求都重新分配
+	paramsPool sync.Pool
+}
+
+// getParams从paramsPool获取1个长度为0、容量至少为MaxParams的Params切片
+// MaxParams会随着addRoute单调增大，池中可能还留有按更早、更小的MaxParams
+// 分配的切片，这里丢弃容量不足的缓存切片，避免getValue写入时越界panic
+func (r *Router) getParams() *Params {
+	if ps, ok := r.paramsPool.Get().(*Params); ok && cap(*ps) >= r.MaxParams {
+		*ps = (*ps)[0:0]
+		return ps
+	}
+	ps := make(Params, 0, r.MaxParams)
+	return &ps
+}
+
+// putParams将Params切片归还paramsPool
+func (r *Router) putParams(ps *Params) {
+	if ps != nil {
+		r.paramsPool.Put(ps)
+	}
 }
 
 // 保证Router实现了http.Handler接口
@@ -152,24 +207,53 @@ func (r *Router) DELETE(path string, handle Handle) {
 	r.Handle("DELETE", path, handle)
 }
 
+// httpMethods是Any注册路由时使用的标准HTTP方法集合
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// Any为所有标准HTTP方法注册同一个处理函数
+func (r *Router) Any(path string, handle Handle) {
+	r.Match(httpMethods, path, handle)
+}
+
+// Match为调用方指定的方法集合注册同一个处理函数
+func (r *Router) Match(methods []string, path string, handle Handle) {
+	for _, method := range methods {
+		r.Handle(method, path, handle)
+	}
+}
+
 // 根据请求方法和请求路径注册新的路由
 func (r *Router) Handle(method, path string, handle Handle) {
+	r.handleWithMiddleware(method, path, handle, r.middleware)
+}
+
+// handleWithMiddleware是Handle的内部实现，额外接受1组中间件
+// 供RouterGroup复用，使分组注册的路由也能走同一条插入路径
+func (r *Router) handleWithMiddleware(method, path string, handle Handle, mw []Middleware) {
 	if path[0] != '/' {
 		panic("path must begin with '/' in path '" + path + "'")
 	}
 
 	if r.trees == nil {
-		r.trees = make(map[string]*node)
+		r.trees = make(map[string]routeTree)
 	}
 
-	// 树的第1级为请求方法，GET->node
+	// 树的第1级为请求方法，GET->routeTree
 	root := r.trees[method]
 	if root == nil {
-		root = new(node)
+		root = newRouteTree(r.UseRegexTree)
 		r.trees[method] = root
 	}
 
-	root.addRoute(path, handle)
+	root.addRoute(path, wrapMiddleware(handle, mw))
+
+	// 只有addRoute没有panic(重复路径、冲突的通配符等)才记录RouteInfo，
+	// 否则Routes()会展示1条从未真正写入前缀树的路由
+	r.routes = append(r.routes, RouteInfo{Method: method, Path: path, HandlerName: handlerName(handle)})
+
+	if n := int(countParams(path)); n > r.MaxParams {
+		r.MaxParams = n
+	}
 }
 
 // 将http.HandlerFunc函数适配成请求处理函数
@@ -192,21 +276,37 @@ func (r *Router) ServeFiles(path string, root http.FileSystem) {
 	r.GET(path, func(w http.ResponseWriter, req *http.Request, ps Params) {
 		// filepath是约定的参数名
 		req.URL.Path = ps.ByName("filepath")
+		r.pushAssetsFor(w, req.URL.Path)
 		fileServer.ServeHTTP(w, req)
 	})
 }
 
 // 捕获异常进行处理的函数
 func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
-	if rcv := recover(); rcv != nil {
-		r.PanicHandler(w, req, rcv)
+	rcv := recover()
+	if rcv == nil {
+		return
 	}
+
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(w, req, &RouterError{
+			Kind:  ErrorKindPanic,
+			Cause: rcv,
+			Stack: debug.Stack(),
+		})
+		return
+	}
+
+	r.PanicHandler(w, req, rcv)
 }
 
 // 根据请求方法和路径查找对应的处理函数
+// 返回的Params归调用方所有，不经过paramsPool，因此可以在调用返回之后继续使用
 func (r *Router) Lookup(method, path string) (Handle, Params, bool) {
 	if root := r.trees[method]; root != nil {
-		return root.getValue(path)
+		ps := make(Params, 0, r.MaxParams)
+		handle, tsr := root.getValue(path, &ps)
+		return handle, ps, tsr
 	}
 	return nil, nil, false
 }
@@ -233,7 +333,9 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 			}
 
 			// 根据请求方法和路径在前缀树查找处理函数
-			handle, _, _ := r.trees[method].getValue(path)
+			ps := r.getParams()
+			handle, _ := r.trees[method].getValue(path, ps)
+			r.putParams(ps)
 			if handle != nil {
 				if len(allow) == 0 {
 					allow = method
@@ -252,18 +354,43 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 // ServeHTTP函数实现了http.Handler接口
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// 请求处理出现异常，可以注册1个处理函数
-	if r.PanicHandler != nil {
+	if r.PanicHandler != nil || r.ErrorHandler != nil {
 		defer r.recv(w, req)
 	}
 
 	path := req.URL.Path
 
+	// 请求路径既不以'/'开头也不是OPTIONS *这种特殊形式，视为不合法的请求，
+	// 不再尝试前缀树查找
+	if path == "" || (path[0] != '/' && path != "*") {
+		if r.ErrorHandler != nil {
+			r.ErrorHandler(w, req, &RouterError{Kind: ErrorKindBadRequest})
+		} else {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		}
+		return
+	}
+
+	// 配置开启HTTP/2 Server Push的时候，将http.Pusher注入请求上下文
+	// 供处理函数通过Pusher(ctx)获取
+	if r.EnablePush {
+		if pusher, ok := w.(http.Pusher); ok {
+			req = req.WithContext(context.WithValue(req.Context(), PusherKey, pusher))
+		}
+	}
+
 	// 如果根据请求方法获取到前缀树，从前缀树查找对应的处理函数
 	if root := r.trees[req.Method]; root != nil {
-		if handle, ps, tsr := root.getValue(path); handle != nil {
-			handle(w, req, ps)
+		ps := r.getParams()
+		handle, tsr := root.getValue(path, ps)
+		if handle != nil {
+			handle(w, req, *ps)
+			r.putParams(ps)
 			return
-		} else if req.Method != "CONNECT" && path != "/" {
+		}
+		r.putParams(ps)
+
+		if req.Method != "CONNECT" && path != "/" {
 			// Permanent redirect
 			code := 301
 			if req.Method != "GET" {
@@ -311,9 +438,15 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		if r.HandleMethodNotAllowed {
 			if allow := r.allowed(path, req.Method); len(allow) > 0 {
 				w.Header().Set("Allow", allow)
-				if r.MethodNotAllowed != nil {
+				g := r.groupFor(path)
+				switch {
+				case g != nil && g.methodNotAllowed != nil:
+					g.methodNotAllowed.ServeHTTP(w, req)
+				case r.ErrorHandler != nil:
+					r.ErrorHandler(w, req, &RouterError{Kind: ErrorKindMethodNotAllowed})
+				case r.MethodNotAllowed != nil:
 					r.MethodNotAllowed.ServeHTTP(w, req)
-				} else {
+				default:
 					http.Error(w,
 						http.StatusText(http.StatusMethodNotAllowed),
 						http.StatusMethodNotAllowed,
@@ -324,8 +457,12 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	// 处理Not Found（404）
-	if r.NotFound != nil {
+	// 处理Not Found（404），分组注册的覆盖优先于Router级别的ErrorHandler/NotFound
+	if g := r.groupFor(path); g != nil && g.notFound != nil {
+		g.notFound.ServeHTTP(w, req)
+	} else if r.ErrorHandler != nil {
+		r.ErrorHandler(w, req, &RouterError{Kind: ErrorKindNotFound})
+	} else if r.NotFound != nil {
 		r.NotFound.ServeHTTP(w, req)
 	} else {
 		http.NotFound(w, req)
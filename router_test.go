@@ -0,0 +1,38 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 复现场景：先注册只有1个参数的路由并处理1次请求(池中缓存1个容量为1的切片)，
+// 再注册参数更多的路由，随后的请求不能因为复用了容量不足的切片而panic
+func TestParamsPoolGrowsWithMaxParams(t *testing.T) {
+	r := New()
+
+	r.GET("/a/:x", func(w http.ResponseWriter, req *http.Request, ps Params) {})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/a/1", nil)
+	r.ServeHTTP(w, req)
+
+	var got Params
+	r.GET("/b/:x/:y/:z", func(w http.ResponseWriter, req *http.Request, ps Params) {
+		got = ps
+	})
+
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			t.Fatalf("request panicked after MaxParams grew: %v", rcv)
+		}
+	}()
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/b/1/2/3", nil)
+	r.ServeHTTP(w, req)
+
+	if got.ByName("x") != "1" || got.ByName("y") != "2" || got.ByName("z") != "3" {
+		t.Fatalf("got params %v, want x=1 y=2 z=3", got)
+	}
+}
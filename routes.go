@@ -0,0 +1,100 @@
+package httprouter
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteInfo描述1条已注册的路由，由Routes()返回
+type RouteInfo struct {
+	Method      string
+	Path        string
+	HandlerName string
+}
+
+// Routes返回当前已注册的全部路由，顺序为注册顺序
+func (r *Router) Routes() []RouteInfo {
+	routes := make([]RouteInfo, len(r.routes))
+	copy(routes, r.routes)
+	return routes
+}
+
+// HandleNamed注册1条命名路由，之后可以通过URL(name, params...)反向生成路径，
+// 实现方式是额外维护1份name -> 原始路径模板的映射，注册路径本身仍然走Handle
+//
+// URL()只支持替换:name/*name这2种参数，因此带{name:pattern}约束参数的路径
+// 不能通过HandleNamed注册，否则反向生成时无法区分约束部分和静态文本
+func (r *Router) HandleNamed(method, name, path string, handle Handle) {
+	if hasRegexConstraint(path) {
+		panic("httprouter: named route '" + name + "' must not contain '{name:pattern}' constraint parameters, URL() does not support them")
+	}
+	if r.routeTemplates == nil {
+		r.routeTemplates = make(map[string]string)
+	}
+	if _, exists := r.routeTemplates[name]; exists {
+		panic("httprouter: route name '" + name + "' already registered")
+	}
+
+	r.Handle(method, path, handle)
+
+	// 只有Handle没有panic(重复路径、冲突的通配符等)才记录模板，否则URL()会
+	// 为1个从未真正注册成功的路由生成看似有效实则404的路径
+	r.routeTemplates[name] = path
+}
+
+// URL根据HandleNamed注册的名字和路径模板反向生成请求路径，params按路径模板中
+// :name/*name出现的顺序依次替换；:name的值会被url.PathEscape转义，*name的值不转义，
+// 去掉前导'/'之后拼接，因为通配符参数本身可能包含路径分隔符
+func (r *Router) URL(name string, params ...string) (string, error) {
+	tmpl, ok := r.routeTemplates[name]
+	if !ok {
+		return "", errors.New("httprouter: no route named '" + name + "'")
+	}
+
+	segments := strings.Split(tmpl, "/")
+	var sb strings.Builder
+	idx := 0
+
+	for i, seg := range segments {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		if seg == "" {
+			continue
+		}
+
+		switch seg[0] {
+		case ':':
+			if idx >= len(params) {
+				return "", errors.New("httprouter: missing parameter for '" + seg + "' in route '" + name + "'")
+			}
+			sb.WriteString(url.PathEscape(params[idx]))
+			idx++
+		case '*':
+			if idx >= len(params) {
+				return "", errors.New("httprouter: missing parameter for '" + seg + "' in route '" + name + "'")
+			}
+			// *name的取值(例如ps.ByName("filepath"))按照约定自带前导'/'，
+			// 而上面已经为这一段写过1个'/'分隔符，这里去掉重复的前导'/'
+			sb.WriteString(strings.TrimPrefix(params[idx], "/"))
+			idx++
+		default:
+			sb.WriteString(seg)
+		}
+	}
+
+	if idx != len(params) {
+		return "", errors.New("httprouter: too many parameters for route '" + name + "'")
+	}
+
+	return sb.String(), nil
+}
+
+// handlerName获取Handle对应函数的完整名字，用于Routes()展示，匿名函数会得到
+// 类似"pkg.Func.func1"的名字
+func handlerName(h Handle) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
@@ -0,0 +1,69 @@
+package httprouter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestURLRoundTripsNamedRoute(t *testing.T) {
+	r := New()
+	r.HandleNamed("GET", "user", "/users/:id/files/*filepath", noopHandle)
+
+	got, err := r.URL("user", "42", "/a/b.txt")
+	if err != nil {
+		t.Fatalf("URL returned error: %v", err)
+	}
+	if want := "/users/42/files/a/b.txt"; got != want {
+		t.Fatalf("URL = %q, want %q", got, want)
+	}
+}
+
+func TestURLRejectsMissingAndExtraParams(t *testing.T) {
+	r := New()
+	r.HandleNamed("GET", "user", "/users/:id", noopHandle)
+
+	if _, err := r.URL("user"); err == nil {
+		t.Fatalf("expected error for missing parameter")
+	}
+	if _, err := r.URL("user", "1", "2"); err == nil {
+		t.Fatalf("expected error for extra parameter")
+	}
+}
+
+// HandleNamed必须拒绝带{name:pattern}约束参数的路径，因为URL()只认识
+// :name/*name这2种参数，无法反向生成约束部分
+func TestHandleNamedRejectsRegexConstraintPath(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected HandleNamed to panic for a regex-constrained path")
+		}
+	}()
+
+	r := New()
+	r.HandleNamed("GET", "user", "/users/{id:[0-9]+}", noopHandle)
+}
+
+func TestRoutesReflectsOnlySuccessfullyAddedRoutes(t *testing.T) {
+	r := New()
+	r.GET("/dup", noopHandle)
+
+	func() {
+		defer func() { recover() }()
+		r.GET("/dup", noopHandle)
+	}()
+
+	routes := r.Routes()
+	if len(routes) != 1 {
+		t.Fatalf("Routes() = %v, want exactly 1 entry for /dup", routes)
+	}
+}
+
+func TestHandlerNameReportsFunctionName(t *testing.T) {
+	r := New()
+	r.GET("/named", func(w http.ResponseWriter, req *http.Request, ps Params) {})
+
+	routes := r.Routes()
+	if len(routes) != 1 || routes[0].HandlerName == "" {
+		t.Fatalf("Routes() = %v, want 1 entry with a non-empty HandlerName", routes)
+	}
+}
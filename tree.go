@@ -0,0 +1,470 @@
+package httprouter
+
+import (
+	"strings"
+	"unicode"
+)
+
+func min(a, b int) int {
+	if a <= b {
+		return a
+	}
+	return b
+}
+
+// countParams统计路径中命名参数(:name)和通配符参数(*name)的数量
+func countParams(path string) uint8 {
+	var n uint
+	for i := 0; i < len(path); i++ {
+		if path[i] != ':' && path[i] != '*' {
+			continue
+		}
+		n++
+	}
+	if n >= 255 {
+		return 255
+	}
+	return uint8(n)
+}
+
+type nodeType uint8
+
+const (
+	static nodeType = iota
+	root
+	param
+	catchAll
+)
+
+// node是基数树(radix tree)的节点，每个节点保存1段公共路径前缀
+// 以及命名参数(:name)和通配符参数(*name)的子树
+type node struct {
+	path      string
+	wildChild bool
+	nType     nodeType
+	indices   string
+	children  []*node
+	handle    Handle
+	priority  uint32
+}
+
+// incrementChildPrio给pos位置的子节点优先级加1，并在必要时调整顺序
+// 保证高频命中的子节点排在前面，加速查找
+func (n *node) incrementChildPrio(pos int) int {
+	n.children[pos].priority++
+	prio := n.children[pos].priority
+
+	newPos := pos
+	for newPos > 0 && n.children[newPos-1].priority < prio {
+		n.children[newPos-1], n.children[newPos] = n.children[newPos], n.children[newPos-1]
+		newPos--
+	}
+
+	if newPos != pos {
+		n.indices = n.indices[:newPos] + n.indices[pos:pos+1] + n.indices[newPos:pos] + n.indices[pos+1:]
+	}
+
+	return newPos
+}
+
+// addRoute将1条新路由加入到以该节点为根的子树
+func (n *node) addRoute(path string, handle Handle) {
+	fullPath := path
+	n.priority++
+	numParams := countParams(path)
+
+	if len(n.path) > 0 || len(n.children) > 0 {
+	walk:
+		for {
+			i := 0
+			max := min(len(path), len(n.path))
+			for i < max && path[i] == n.path[i] {
+				i++
+			}
+
+			if i < len(n.path) {
+				child := node{
+					path:      n.path[i:],
+					wildChild: n.wildChild,
+					indices:   n.indices,
+					children:  n.children,
+					handle:    n.handle,
+					priority:  n.priority - 1,
+				}
+
+				n.children = []*node{&child}
+				n.indices = string([]byte{n.path[i]})
+				n.path = path[:i]
+				n.handle = nil
+				n.wildChild = false
+			}
+
+			if i < len(path) {
+				path = path[i:]
+
+				if n.wildChild {
+					n = n.children[0]
+					n.priority++
+					numParams--
+
+					if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
+						n.nType != catchAll &&
+						(len(n.path) >= len(path) || path[len(n.path)] == '/') {
+						continue walk
+					}
+
+					var pathSeg string
+					if n.nType == catchAll {
+						pathSeg = path
+					} else {
+						pathSeg = strings.SplitN(path, "/", 2)[0]
+					}
+					prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
+					panic("'" + pathSeg +
+						"' in new path '" + fullPath +
+						"' conflicts with existing wildcard '" + n.path +
+						"' in existing prefix '" + prefix +
+						"'")
+				}
+
+				c := path[0]
+
+				if n.nType == param && c == '/' && len(n.children) == 1 {
+					n = n.children[0]
+					n.priority++
+					continue walk
+				}
+
+				for i := 0; i < len(n.indices); i++ {
+					if c == n.indices[i] {
+						i = n.incrementChildPrio(i)
+						n = n.children[i]
+						continue walk
+					}
+				}
+
+				if c != ':' && c != '*' {
+					n.indices += string([]byte{c})
+					child := &node{}
+					n.children = append(n.children, child)
+					n.incrementChildPrio(len(n.indices) - 1)
+					n = child
+				}
+				n.insertChild(numParams, path, fullPath, handle)
+				return
+			}
+
+			if n.handle != nil {
+				panic("a Handle is already registered for path '" + fullPath + "'")
+			}
+			n.handle = handle
+			return
+		}
+	}
+
+	n.insertChild(numParams, path, fullPath, handle)
+	n.nType = root
+}
+
+func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle) {
+	var offset int
+
+	for i, max := 0, len(path); numParams > 0; i++ {
+		c := path[i]
+		if c != ':' && c != '*' {
+			continue
+		}
+
+		end := i + 1
+		for end < max && path[end] != '/' {
+			switch path[end] {
+			case ':', '*':
+				panic("only one wildcard per path segment is allowed, has: '" +
+					path[i:] + "' in path '" + fullPath + "'")
+			default:
+				end++
+			}
+		}
+
+		if len(n.children) > 0 {
+			panic("wildcard route '" + path[i:end] +
+				"' conflicts with existing children in path '" + fullPath + "'")
+		}
+
+		if end-i < 2 {
+			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+		}
+
+		if c == ':' {
+			if i > 0 {
+				n.path = path[offset:i]
+				offset = i
+			}
+
+			child := &node{
+				nType: param,
+			}
+			n.children = []*node{child}
+			n.wildChild = true
+			n = child
+			n.priority++
+			numParams--
+
+			if end < max {
+				n.path = path[offset:end]
+				offset = end
+
+				child := &node{
+					priority: 1,
+				}
+				n.children = []*node{child}
+				n = child
+			}
+		} else {
+			if end != max || numParams > 1 {
+				panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
+			}
+
+			if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
+				panic("catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'")
+			}
+
+			i--
+			if path[i] != '/' {
+				panic("no / before catch-all in path '" + fullPath + "'")
+			}
+
+			n.path = path[offset:i]
+
+			child := &node{
+				wildChild: true,
+				nType:     catchAll,
+			}
+			n.children = []*node{child}
+			n.indices = string(path[i])
+			n = child
+			n.priority++
+
+			child = &node{
+				path:     path[i:],
+				nType:    catchAll,
+				handle:   handle,
+				priority: 1,
+			}
+			n.children = []*node{child}
+
+			return
+		}
+
+		i = end
+	}
+
+	n.path = path[offset:]
+	n.handle = handle
+}
+
+// getValue在以该节点为根的子树查找匹配的处理函数
+// po指向调用方(通常来自Router.paramsPool)预先分配好的Params切片，命中的命名参数
+// 和通配符参数直接追加写入*po，避免每次查找都重新分配切片
+func (n *node) getValue(path string, po *Params) (handle Handle, tsr bool) {
+walk:
+	for {
+		if len(path) > len(n.path) {
+			if path[:len(n.path)] != n.path {
+				tsr = (path == "/") ||
+					(len(n.path) == len(path)+1 && n.path[len(path)] == '/' &&
+						path == n.path[:len(n.path)-1] && n.handle != nil)
+				return
+			}
+
+			path = path[len(n.path):]
+
+			if !n.wildChild {
+				c := path[0]
+				for i := 0; i < len(n.indices); i++ {
+					if c == n.indices[i] {
+						n = n.children[i]
+						continue walk
+					}
+				}
+
+				tsr = (path == "/" && n.handle != nil)
+				return
+			}
+
+			n = n.children[0]
+			switch n.nType {
+			case param:
+				end := 0
+				for end < len(path) && path[end] != '/' {
+					end++
+				}
+
+				p := *po
+				i := len(p)
+				p = p[:i+1]
+				p[i].Key = n.path[1:]
+				p[i].Value = path[:end]
+				*po = p
+
+				if end < len(path) {
+					if len(n.children) > 0 {
+						path = path[end:]
+						n = n.children[0]
+						continue walk
+					}
+
+					tsr = (len(path) == end+1)
+					return
+				}
+
+				if handle = n.handle; handle != nil {
+					return
+				} else if len(n.children) == 1 {
+					n = n.children[0]
+					tsr = (n.path == "/" && n.handle != nil)
+				}
+
+				return
+
+			case catchAll:
+				p := *po
+				i := len(p)
+				p = p[:i+1]
+				p[i].Key = n.path[2:]
+				p[i].Value = path
+				*po = p
+
+				handle = n.handle
+				return
+
+			default:
+				panic("invalid node type")
+			}
+		} else if path == n.path {
+			if handle = n.handle; handle != nil {
+				return
+			}
+
+			if path == "/" && n.wildChild && n.nType != root {
+				tsr = true
+				return
+			}
+
+			for i := 0; i < len(n.indices); i++ {
+				if n.indices[i] == '/' {
+					n = n.children[i]
+					tsr = (len(n.path) == 1 && n.handle != nil) ||
+						(n.nType == catchAll && n.children[0].handle != nil)
+					return
+				}
+			}
+
+			return
+		}
+
+		tsr = (path == "/") ||
+			(len(n.path) == len(path)+1 && n.path[len(path)] == '/' &&
+				path == n.path[:len(n.path)-1] && n.handle != nil)
+		return
+	}
+}
+
+// findCaseInsensitivePath忽略大小写进行匹配，fixTrailingSlash为true时同时尝试修正斜杠
+func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPath []byte, found bool) {
+	ciPath = make([]byte, 0, len(path)+1)
+
+	insensitive := n.path
+	if len(path) < len(insensitive) || !strings.EqualFold(path[:len(insensitive)], insensitive) {
+		if fixTrailingSlash && path == "/" {
+			return ciPath, true
+		}
+		if len(path)+1 == len(insensitive) && insensitive[len(path)] == '/' &&
+			strings.EqualFold(path, insensitive[:len(path)]) &&
+			n.handle != nil {
+			return append(ciPath, n.path...), true
+		}
+		return ciPath, false
+	}
+
+	ciPath = append(ciPath, n.path...)
+
+	if path = path[len(n.path):]; len(path) == 0 {
+		if n.handle != nil {
+			return ciPath, true
+		}
+
+		if fixTrailingSlash {
+			for i := 0; i < len(n.indices); i++ {
+				if n.indices[i] == '/' {
+					n = n.children[i]
+					if (len(n.path) == 1 && n.handle != nil) ||
+						(n.nType == catchAll && n.children[0].handle != nil) {
+						return append(ciPath, '/'), true
+					}
+					return ciPath, false
+				}
+			}
+		}
+		return ciPath, false
+	}
+
+	if !n.wildChild {
+		r := unicode.ToLower(rune(path[0]))
+		for i, index := range n.indices {
+			if r == unicode.ToLower(index) {
+				out, found := n.children[i].findCaseInsensitivePath(path, fixTrailingSlash)
+				if found {
+					return append(ciPath, out...), true
+				}
+			}
+		}
+
+		if fixTrailingSlash && path == "/" && n.handle != nil {
+			return ciPath, true
+		}
+		return ciPath, false
+	}
+
+	n = n.children[0]
+	switch n.nType {
+	case param:
+		end := 0
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
+
+		ciPath = append(ciPath, path[:end]...)
+
+		if end < len(path) {
+			if len(n.children) > 0 {
+				path = path[end:]
+				out, found := n.children[0].findCaseInsensitivePath(path, fixTrailingSlash)
+				if found {
+					return append(ciPath, out...), true
+				}
+			}
+
+			if fixTrailingSlash && len(path) == end+1 {
+				return ciPath, true
+			}
+			return ciPath, false
+		}
+
+		if n.handle != nil {
+			return ciPath, true
+		} else if fixTrailingSlash && len(n.children) == 1 {
+			n = n.children[0]
+			if n.path == "/" && n.handle != nil {
+				return append(ciPath, '/'), true
+			}
+		}
+		return ciPath, false
+
+	case catchAll:
+		return append(ciPath, path...), true
+
+	default:
+		panic("invalid node type")
+	}
+}
@@ -0,0 +1,182 @@
+package httprouter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// routeTree是前缀树的抽象，Router.trees改为持有该接口而不是具体的*node
+// 从而可以在不改动Handle/ServeHTTP调用方式的前提下替换匹配算法
+type routeTree interface {
+	addRoute(path string, h Handle)
+	getValue(path string, po *Params) (Handle, bool)
+	findCaseInsensitivePath(path string, fixTrailingSlash bool) ([]byte, bool)
+}
+
+// 保证现有的基数树实现了routeTree接口
+var _ routeTree = (*node)(nil)
+
+// hasRegexConstraint判断路径中是否包含 {name:pattern} 形式的约束参数
+func hasRegexConstraint(path string) bool {
+	return strings.IndexByte(path, '{') >= 0
+}
+
+// mixedTree是routeTree的默认实现：静态路径和:name/*name参数路由继续使用
+// 基数树(node)以保持原有性能，{name:pattern}形式的约束参数路由则交给regexTree，
+// 两者共享同一个method对应的路由表
+type mixedTree struct {
+	radix *node
+	regex *regexTree
+	force bool
+}
+
+// newRouteTree创建1棵routeTree，force为true时即使是:name/*name风格的路径
+// 也统一交给regexTree处理，对应Router.UseRegexTree全局开关
+func newRouteTree(force bool) routeTree {
+	return &mixedTree{radix: new(node), force: force}
+}
+
+func (t *mixedTree) addRoute(path string, h Handle) {
+	if t.force || hasRegexConstraint(path) {
+		if t.regex == nil {
+			t.regex = new(regexTree)
+		}
+		t.regex.addRoute(path, h)
+		return
+	}
+	t.radix.addRoute(path, h)
+}
+
+func (t *mixedTree) getValue(path string, po *Params) (Handle, bool) {
+	// 基数树匹配失败之前可能已经往*po写入了命中一半的通配符参数(例如
+	// 走到某个:name分支之后才发现没有注册Handle)，尝试regex树之前必须
+	// 把*po截回原始长度，否则regex路由会混入不属于自己的参数
+	before := len(*po)
+	handle, tsr := t.radix.getValue(path, po)
+	if handle != nil {
+		return handle, tsr
+	}
+	*po = (*po)[:before]
+	if t.regex != nil {
+		if handle, rtsr := t.regex.getValue(path, po); handle != nil {
+			return handle, rtsr
+		}
+	}
+	// 两棵树都没有命中时保留基数树给出的tsr，供RedirectTrailingSlash使用
+	return nil, tsr
+}
+
+func (t *mixedTree) findCaseInsensitivePath(path string, fixTrailingSlash bool) ([]byte, bool) {
+	return t.radix.findCaseInsensitivePath(path, fixTrailingSlash)
+}
+
+// regexRoute保存1条编译之后的约束参数路由
+type regexRoute struct {
+	re     *regexp.Regexp
+	names  []string
+	handle Handle
+}
+
+// regexTree是routeTree的第2种实现，支持 {name:pattern} 形式的约束参数，
+// 例如 /users/{id:[0-9]+} 或 /date/{d:\d{4}-\d{2}-\d{2}}
+// 匹配按注册顺序遍历正则表达式，适合约束参数较少的场景；
+// 大量静态路由仍然应该使用默认的基数树以保持零分配的查找性能
+type regexTree struct {
+	routes []*regexRoute
+}
+
+func (t *regexTree) addRoute(path string, h Handle) {
+	re, names, err := compileConstraintPath(path)
+	if err != nil {
+		panic(err)
+	}
+	t.routes = append(t.routes, &regexRoute{re: re, names: names, handle: h})
+}
+
+func (t *regexTree) getValue(path string, po *Params) (Handle, bool) {
+	for _, route := range t.routes {
+		m := route.re.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+
+		// 分组是按名字而不是位置取值，避免pattern自带圆括号分组
+		// (例如{id:(foo|bar)})时错位到错误的分组
+		p := *po
+		for _, name := range route.names {
+			p = append(p, Param{Key: name, Value: m[route.re.SubexpIndex(name)]})
+		}
+		*po = p
+		return route.handle, false
+	}
+	return nil, false
+}
+
+// findCaseInsensitivePath约束参数路由不支持大小写/斜杠修正重定向
+func (t *regexTree) findCaseInsensitivePath(path string, fixTrailingSlash bool) ([]byte, bool) {
+	return nil, false
+}
+
+// compileConstraintPath将 {name:pattern} 形式的路径模板编译成正则表达式，
+// 静态部分原样转义，约束部分转换为带名字的分组
+func compileConstraintPath(path string) (*regexp.Regexp, []string, error) {
+	var sb strings.Builder
+	var names []string
+	sb.WriteByte('^')
+
+	for i := 0; i < len(path); {
+		if path[i] != '{' {
+			sb.WriteString(regexp.QuoteMeta(path[i : i+1]))
+			i++
+			continue
+		}
+
+		// 约束部分的正则表达式本身可能包含花括号(例如{d:\d{4}-\d{2}-\d{2}})，
+		// 因此按嵌套深度寻找与起始'{'匹配的那个'}'，而不是第1个出现的'}'
+		rest := path[i+1:]
+		depth := 1
+		end := -1
+		for j := 0; j < len(rest); j++ {
+			switch rest[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+			if end >= 0 {
+				break
+			}
+		}
+		if end < 0 {
+			return nil, nil, errors.New("httprouter: unterminated '{' in path '" + path + "'")
+		}
+
+		seg := rest[:end]
+		i += end + 2
+
+		name, pattern := seg, ".*"
+		if idx := strings.IndexByte(seg, ':'); idx >= 0 {
+			name, pattern = seg[:idx], seg[idx+1:]
+		}
+		if name == "" {
+			return nil, nil, errors.New("httprouter: unnamed constraint parameter in path '" + path + "'")
+		}
+
+		names = append(names, name)
+		// 用命名分组而不是按位置的分组，否则pattern本身包含圆括号分组时
+		// (例如{id:(foo|bar)})会让FindStringSubmatch的分组序号和names错位
+		sb.WriteString("(?P<" + name + ">" + pattern + ")")
+	}
+
+	sb.WriteByte('$')
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return re, names, nil
+}
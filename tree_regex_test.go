@@ -0,0 +1,84 @@
+package httprouter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func noopHandle(http.ResponseWriter, *http.Request, Params) {}
+
+func TestCompileConstraintPathNestedBraces(t *testing.T) {
+	re, names, err := compileConstraintPath(`/date/{d:\d{4}-\d{2}-\d{2}}`)
+	if err != nil {
+		t.Fatalf("compileConstraintPath returned error: %v", err)
+	}
+	if len(names) != 1 || names[0] != "d" {
+		t.Fatalf("names = %v, want [d]", names)
+	}
+	if !re.MatchString("/date/2024-05-01") {
+		t.Fatalf("compiled regex %q did not match /date/2024-05-01", re.String())
+	}
+}
+
+func TestRegexTreeMatchesCanonicalDateExample(t *testing.T) {
+	tr := newRouteTree(false)
+	tr.addRoute(`/date/{d:\d{4}-\d{2}-\d{2}}`, noopHandle)
+
+	ps := make(Params, 0, 1)
+	handle, _ := tr.getValue("/date/2024-05-01", &ps)
+	if handle == nil {
+		t.Fatalf("expected a match for /date/2024-05-01")
+	}
+	if got := ps.ByName("d"); got != "2024-05-01" {
+		t.Fatalf("ps.ByName(%q) = %q, want %q", "d", got, "2024-05-01")
+	}
+}
+
+// 对应路由: /a/:x/c (基数树) + /a/{y:.+} (正则树)，请求/a/hello/world应该只
+// 命中正则路由，且不能混入基数树尝试匹配:x时写入的陈旧参数
+func TestMixedTreeDoesNotLeakParamsBetweenRadixAndRegex(t *testing.T) {
+	tr := newRouteTree(false)
+	tr.addRoute("/a/:x/c", noopHandle)
+	tr.addRoute("/a/{y:.+}", noopHandle)
+
+	ps := make(Params, 0, 2)
+	handle, _ := tr.getValue("/a/hello/world", &ps)
+	if handle == nil {
+		t.Fatalf("expected the regex route to match /a/hello/world")
+	}
+
+	if _, ok := findParam(ps, "x"); ok {
+		t.Fatalf("ps = %v, should not contain stale 'x' from the failed radix match", ps)
+	}
+	if got := ps.ByName("y"); got != "hello/world" {
+		t.Fatalf("ps.ByName(%q) = %q, want %q", "y", got, "hello/world")
+	}
+}
+
+// 约束pattern本身带圆括号分组时，参数值必须按分组名字而不是位置取，
+// 否则/users/{id:(foo|bar)}/{name:.*}这样的路由会把id的分组错位给name
+func TestRegexTreeHandlesPatternWithOwnCapturingGroup(t *testing.T) {
+	tr := newRouteTree(false)
+	tr.addRoute(`/users/{id:(foo|bar)}/{name:.*}`, noopHandle)
+
+	ps := make(Params, 0, 2)
+	handle, _ := tr.getValue("/users/foo/alice", &ps)
+	if handle == nil {
+		t.Fatalf("expected a match for /users/foo/alice")
+	}
+	if got := ps.ByName("id"); got != "foo" {
+		t.Fatalf("ps.ByName(%q) = %q, want %q", "id", got, "foo")
+	}
+	if got := ps.ByName("name"); got != "alice" {
+		t.Fatalf("ps.ByName(%q) = %q, want %q", "name", got, "alice")
+	}
+}
+
+func findParam(ps Params, key string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}